@@ -1,7 +1,14 @@
 // /proc/net/rpc/nfsd parsing documented by https://www.svennd.be/nfsd-stats-explained-procnetrpcnfsd/
 package procfs
 
-import ()
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
 
 // rc line: Reply Cache
 type NFSdReplyCache struct {
@@ -112,49 +119,316 @@ type NFSdv4Stats struct {
 	Compound uint64
 }
 
-// proc4ops line: NFSv4 operations
-// Variable list, see:
-// v4.0 https://tools.ietf.org/html/rfc3010 (38 operations)
-// v4.1 https://tools.ietf.org/html/rfc5661 (58 operations)
-// v4.2 https://tools.ietf.org/html/draft-ietf-nfsv4-minorversion2-41 (71 operations)
+// proc4ops line: NFSv4 operations.
+//
+// The number of operations reported varies with the NFSv4 minor version in
+// use:
+//
+//	v4.0 https://tools.ietf.org/html/rfc3010 (38 operations)
+//	v4.1 https://tools.ietf.org/html/rfc5661 (58 operations)
+//	v4.2 https://tools.ietf.org/html/draft-ietf-nfsv4-minorversion2-41 (71 operations)
+//
+// so the per-operation counters are kept in a slice indexed by operation
+// number rather than as named fields.
 type NFSdv4Ops struct {
-	Values uint64 // Variable depending on v4.x sub-version.
-	Op0Unused uint64
-	Op1Unused uint64
-	Op2Future uint64
-	Access uint64
-	Close
-	Commit
-	Create
-	DelegPurge
-	DelegReturn
-	GetAttr
-	GetFH
-	Link
-	Lock
-	Lockt
-	Locku
-	Lookup
-	LookupRoot
-	Nverify
-	Open
-	OpenAttr
-	OpenConfirm
-	OpenDgrd
-	PutFH
-	PutPubFH
-	PutRootFH
-	Read
-	ReadDir
-	ReadLink
-	Remove
-	Rename
-	Renew
-	RestoreFH
-	SaveFH
-	SecInfo
-	SetAttr
-	Verify
-	Write
-	RelLockOwner
+	Values uint64   // Number of operation counters that follow, taken from the line itself.
+	Ops    []uint64 // Per-operation counters, indexed by NFSv4 operation number.
+}
+
+// NFSdRPCStats models the contents of /proc/net/rpc/nfsd.
+type NFSdRPCStats struct {
+	ReplyCache     NFSdReplyCache
+	FileHandles    NFSdFileHandles
+	InputOutput    NFSdInputOutput
+	Threads        NFSdThreads
+	ReadAheadCache NFSdReadAheadCache
+	Network        NFSdNetwork
+	RPC            NFSdRPC
+	V2Stats        NFSdv2Stats
+	V3Stats        NFSdv3Stats
+	V4Stats        NFSdv4Stats
+	V4Ops          NFSdv4Ops
+}
+
+// NewNFSdRPCStats reads /proc/net/rpc/nfsd and returns the NFS server RPC
+// statistics it contains.
+func (fs FS) NewNFSdRPCStats() (NFSdRPCStats, error) {
+	f, err := os.Open(fs.path("net/rpc/nfsd"))
+	if err != nil {
+		return NFSdRPCStats{}, err
+	}
+	defer f.Close()
+
+	return parseNFSdRPCStats(f)
+}
+
+func parseNFSdRPCStats(r io.Reader) (NFSdRPCStats, error) {
+	var stats NFSdRPCStats
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		// The th (threads) line is followed by a 10-bucket histogram of
+		// floats, not uint64s, so it can't go through the generic
+		// parseUint64s path below.
+		if fields[0] == "th" {
+			threads, err := parseNFSdThreads(fields[1:])
+			if err != nil {
+				return NFSdRPCStats{}, fmt.Errorf("failed to parse NFSd th line %q: %w", scanner.Text(), err)
+			}
+			stats.Threads = threads
+			continue
+		}
+
+		values, err := parseUint64s(fields[1:])
+		if err != nil {
+			return NFSdRPCStats{}, fmt.Errorf("failed to parse NFSd metric line %q: %w", scanner.Text(), err)
+		}
+
+		switch fields[0] {
+		case "rc":
+			if len(values) != 3 {
+				return NFSdRPCStats{}, fmt.Errorf("invalid NFSd rc line %q", scanner.Text())
+			}
+			stats.ReplyCache = NFSdReplyCache{values[0], values[1], values[2]}
+		case "fh":
+			if len(values) != 5 {
+				return NFSdRPCStats{}, fmt.Errorf("invalid NFSd fh line %q", scanner.Text())
+			}
+			stats.FileHandles = NFSdFileHandles{values[0], values[1], values[2], values[3], values[4]}
+		case "io":
+			if len(values) != 2 {
+				return NFSdRPCStats{}, fmt.Errorf("invalid NFSd io line %q", scanner.Text())
+			}
+			stats.InputOutput = NFSdInputOutput{values[0], values[1]}
+		case "ra":
+			if len(values) != 12 {
+				return NFSdRPCStats{}, fmt.Errorf("invalid NFSd ra line %q", scanner.Text())
+			}
+			var ra NFSdReadAheadCache
+			ra.CacheSize = values[0]
+			copy(ra.CacheHistogram[:], values[1:11])
+			ra.NotFound = values[11]
+			stats.ReadAheadCache = ra
+		case "net":
+			if len(values) != 4 {
+				return NFSdRPCStats{}, fmt.Errorf("invalid NFSd net line %q", scanner.Text())
+			}
+			stats.Network = NFSdNetwork{values[0], values[1], values[2], values[3]}
+		case "rpc":
+			if len(values) != 5 {
+				return NFSdRPCStats{}, fmt.Errorf("invalid NFSd rpc line %q", scanner.Text())
+			}
+			stats.RPC = NFSdRPC{values[0], values[1], values[2], values[3], values[4]}
+		case "proc2":
+			stats.V2Stats, err = parseNFSdv2Stats(values)
+			if err != nil {
+				return NFSdRPCStats{}, err
+			}
+		case "proc3":
+			stats.V3Stats, err = parseNFSdv3Stats(values)
+			if err != nil {
+				return NFSdRPCStats{}, err
+			}
+		case "proc4":
+			if len(values) != 3 {
+				return NFSdRPCStats{}, fmt.Errorf("invalid NFSd proc4 line %q", scanner.Text())
+			}
+			stats.V4Stats = NFSdv4Stats{values[0], values[1], values[2]}
+		case "proc4ops":
+			stats.V4Ops, err = parseNFSdv4Ops(values)
+			if err != nil {
+				return NFSdRPCStats{}, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return NFSdRPCStats{}, err
+	}
+
+	return stats, nil
+}
+
+// parseNFSdThreads parses the th line. Only the first two fields (thread
+// count and the count of times all threads were in use) are modeled by
+// NFSdThreads; the remaining fields are a histogram of floats giving the
+// fraction of time spent with a given number of threads in use, which this
+// package does not currently expose.
+func parseNFSdThreads(fields []string) (NFSdThreads, error) {
+	if len(fields) < 2 {
+		return NFSdThreads{}, fmt.Errorf("invalid th line %v", fields)
+	}
+	threads, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return NFSdThreads{}, err
+	}
+	fullCnt, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return NFSdThreads{}, err
+	}
+	return NFSdThreads{Threads: threads, FullCnt: fullCnt}, nil
+}
+
+func parseNFSdv2Stats(values []uint64) (NFSdv2Stats, error) {
+	if len(values) < 1 || uint64(len(values)-1) < values[0] {
+		return NFSdv2Stats{}, fmt.Errorf("invalid NFSd proc2 line %v", values)
+	}
+	var s NFSdv2Stats
+	s.Values = values[0]
+	fields := []*uint64{
+		&s.Null, &s.GetAttr, &s.SetAttr, &s.Root, &s.Lookup, &s.ReadLink,
+		&s.Read, &s.WrCache, &s.Write, &s.Create, &s.Remove, &s.Rename,
+		&s.Link, &s.SymLink, &s.MkDir, &s.RmDir, &s.ReadDir, &s.FsStat,
+	}
+	for i, f := range fields {
+		if uint64(i) < s.Values {
+			*f = values[i+1]
+		}
+	}
+	return s, nil
+}
+
+func parseNFSdv3Stats(values []uint64) (NFSdv3Stats, error) {
+	if len(values) < 1 || uint64(len(values)-1) < values[0] {
+		return NFSdv3Stats{}, fmt.Errorf("invalid NFSd proc3 line %v", values)
+	}
+	var s NFSdv3Stats
+	s.Values = values[0]
+	fields := []*uint64{
+		&s.Null, &s.GetAttr, &s.SetAttr, &s.Lookup, &s.Access, &s.ReadLink,
+		&s.Read, &s.Write, &s.Create, &s.MkDir, &s.SymLink, &s.MkNod,
+		&s.Remove, &s.RmDir, &s.Rename, &s.Link, &s.ReadDir, &s.ReadDirPlus,
+		&s.FsStat, &s.FsInfo, &s.PathConf, &s.Commit,
+	}
+	for i, f := range fields {
+		if uint64(i) < s.Values {
+			*f = values[i+1]
+		}
+	}
+	return s, nil
+}
+
+// parseNFSdv4Ops parses a proc4ops line. The first value is the count of
+// operation counters that follow; the remaining values are read into a
+// slice indexed by NFSv4 operation number, however many there are.
+func parseNFSdv4Ops(values []uint64) (NFSdv4Ops, error) {
+	if len(values) < 1 {
+		return NFSdv4Ops{}, fmt.Errorf("invalid NFSd proc4ops line %v", values)
+	}
+	count := values[0]
+	if uint64(len(values)-1) < count {
+		return NFSdv4Ops{}, fmt.Errorf("proc4ops line declares %d operations but only has %d values", count, len(values)-1)
+	}
+	ops := make([]uint64, count)
+	copy(ops, values[1:1+count])
+	return NFSdv4Ops{Values: count, Ops: ops}, nil
+}
+
+// NFSClientRPCStats models the contents of /proc/net/rpc/nfs, the
+// client-side counterpart to NFSdRPCStats.
+type NFSClientRPCStats struct {
+	Network NFSdNetwork
+	RPC     NFSClientRPC
+	V2Stats NFSdv2Stats
+	V3Stats NFSdv3Stats
+	V4Stats NFSClientV4Stats
+}
+
+// NFSClientRPC is the rpc line of /proc/net/rpc/nfs: calls made, retransmissions,
+// and authentication refreshes.
+type NFSClientRPC struct {
+	RPCCount        uint64
+	Retransmissions uint64
+	AuthRefreshes   uint64
+}
+
+// NFSClientV4Stats is the proc4 line of /proc/net/rpc/nfs. As with
+// NFSdv4Ops, the set of NFSv4 client procedures varies by minor version, so
+// the per-procedure request counts are kept in a slice.
+type NFSClientV4Stats struct {
+	Values uint64
+	Ops    []uint64
+}
+
+// NewNFSClientRPCStats reads /proc/net/rpc/nfs and returns the NFS client
+// RPC statistics it contains.
+func (fs FS) NewNFSClientRPCStats() (NFSClientRPCStats, error) {
+	f, err := os.Open(fs.path("net/rpc/nfs"))
+	if err != nil {
+		return NFSClientRPCStats{}, err
+	}
+	defer f.Close()
+
+	return parseNFSClientRPCStats(f)
+}
+
+func parseNFSClientRPCStats(r io.Reader) (NFSClientRPCStats, error) {
+	var stats NFSClientRPCStats
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		values, err := parseUint64s(fields[1:])
+		if err != nil {
+			return NFSClientRPCStats{}, fmt.Errorf("failed to parse NFS client metric line %q: %w", scanner.Text(), err)
+		}
+
+		switch fields[0] {
+		case "net":
+			if len(values) != 4 {
+				return NFSClientRPCStats{}, fmt.Errorf("invalid NFS client net line %q", scanner.Text())
+			}
+			stats.Network = NFSdNetwork{values[0], values[1], values[2], values[3]}
+		case "rpc":
+			if len(values) != 3 {
+				return NFSClientRPCStats{}, fmt.Errorf("invalid NFS client rpc line %q", scanner.Text())
+			}
+			stats.RPC = NFSClientRPC{values[0], values[1], values[2]}
+		case "proc2":
+			stats.V2Stats, err = parseNFSdv2Stats(values)
+			if err != nil {
+				return NFSClientRPCStats{}, err
+			}
+		case "proc3":
+			stats.V3Stats, err = parseNFSdv3Stats(values)
+			if err != nil {
+				return NFSClientRPCStats{}, err
+			}
+		case "proc4":
+			if len(values) < 1 {
+				return NFSClientRPCStats{}, fmt.Errorf("invalid NFS client proc4 line %q", scanner.Text())
+			}
+			ops, err := parseNFSdv4Ops(values)
+			if err != nil {
+				return NFSClientRPCStats{}, err
+			}
+			stats.V4Stats = NFSClientV4Stats{Values: ops.Values, Ops: ops.Ops}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return NFSClientRPCStats{}, err
+	}
+
+	return stats, nil
+}
+
+// parseUint64s converts a slice of strings into a slice of uint64s.
+func parseUint64s(ss []string) ([]uint64, error) {
+	values := make([]uint64, len(ss))
+	for i, s := range ss {
+		v, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
 }
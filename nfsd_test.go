@@ -0,0 +1,110 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package procfs
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseNFSdRPCStats(t *testing.T) {
+	tests := []struct {
+		name        string
+		fixture     string
+		wantOpCount int
+	}{
+		{"NFSv4.0 (38 ops)", "fixtures/proc/net/rpc/nfsd", 38},
+		{"NFSv4.1 (58 ops)", "fixtures/proc/net/rpc/nfsd41", 58},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.Open(tt.fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			stats, err := parseNFSdRPCStats(f)
+			if err != nil {
+				t.Fatalf("parseNFSdRPCStats: %v", err)
+			}
+
+			if got, want := stats.Threads.Threads, uint64(8); got != want {
+				t.Errorf("Threads.Threads = %d, want %d", got, want)
+			}
+			if got, want := stats.Threads.FullCnt, uint64(0); got != want {
+				t.Errorf("Threads.FullCnt = %d, want %d", got, want)
+			}
+			if got, want := stats.V2Stats.Values, uint64(18); got != want {
+				t.Errorf("V2Stats.Values = %d, want %d", got, want)
+			}
+			if got, want := stats.V3Stats.Values, uint64(22); got != want {
+				t.Errorf("V3Stats.Values = %d, want %d", got, want)
+			}
+			if got, want := stats.V4Ops.Values, uint64(tt.wantOpCount); got != want {
+				t.Errorf("V4Ops.Values = %d, want %d", got, want)
+			}
+			if got, want := len(stats.V4Ops.Ops), tt.wantOpCount; got != want {
+				t.Errorf("len(V4Ops.Ops) = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestParseNFSdRPCStatsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"short rc line", "rc 1 2\n"},
+		{"non-numeric field", "rc 1 2 x\n"},
+		{"proc4ops declares more ops than provided", "proc4ops 3 1 2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseNFSdRPCStats(strings.NewReader(tt.in)); err == nil {
+				t.Error("expected an error, got nil")
+			}
+		})
+	}
+}
+
+func TestParseNFSClientRPCStats(t *testing.T) {
+	f, err := os.Open("fixtures/proc/net/rpc/nfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	stats, err := parseNFSClientRPCStats(f)
+	if err != nil {
+		t.Fatalf("parseNFSClientRPCStats: %v", err)
+	}
+
+	if got, want := stats.RPC.RPCCount, uint64(500); got != want {
+		t.Errorf("RPC.RPCCount = %d, want %d", got, want)
+	}
+	if got, want := stats.V2Stats.Values, uint64(18); got != want {
+		t.Errorf("V2Stats.Values = %d, want %d", got, want)
+	}
+	if got, want := stats.V4Stats.Values, uint64(59); got != want {
+		t.Errorf("V4Stats.Values = %d, want %d", got, want)
+	}
+	if got, want := len(stats.V4Stats.Ops), 59; got != want {
+		t.Errorf("len(V4Stats.Ops) = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package proc provides a cross-platform view of a single process. It is
+// backed by /proc on Linux, where it delegates to procfs.Proc unchanged,
+// and on FreeBSD/amd64 by the kinfo_proc structure returned by the
+// kern.proc.pid sysctl. No other platform has a backend.
+package proc
+
+import "github.com/ncabatoff/procfs"
+
+// ProcessInfo is a cross-platform view of a single process.
+type ProcessInfo interface {
+	// Status returns status information about the process, such as its
+	// name, state, and memory usage.
+	Status() (procfs.ProcStatus, error)
+	// IO returns I/O statistics for the process.
+	IO() (procfs.ProcIO, error)
+	// Stat returns status and scheduling information about the process.
+	Stat() (procfs.ProcStat, error)
+	// Limits returns the resource limits applied to the process.
+	Limits() (procfs.ProcLimits, error)
+}
+
+// NewProcessInfo returns a ProcessInfo for the process with the given PID,
+// using whichever backend is appropriate for the current platform.
+func NewProcessInfo(pid int) (ProcessInfo, error) {
+	return newProcessInfo(pid)
+}
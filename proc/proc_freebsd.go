@@ -0,0 +1,88 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build freebsd
+// +build freebsd
+
+package proc
+
+import (
+	"fmt"
+
+	"github.com/ncabatoff/procfs"
+)
+
+// freebsdProcessInfo implements ProcessInfo on FreeBSD, which has no /proc
+// mount by default, using the kinfo_proc structure returned by the
+// kern.proc.pid sysctl. golang.org/x/sys/unix does not define kinfo_proc
+// for this GOOS (only Darwin's variant), so sysctlKinfoProc unmarshals the
+// raw sysctl bytes itself against FreeBSD's struct kinfo_proc layout; that
+// layout is architecture-specific, so it lives in a per-architecture
+// proc_freebsd_GOARCH.go file. Only proc_freebsd_amd64.go exists.
+type freebsdProcessInfo struct {
+	pid int
+	kp  kinfoProc
+}
+
+func newProcessInfo(pid int) (ProcessInfo, error) {
+	kp, err := sysctlKinfoProc(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	return freebsdProcessInfo{pid: pid, kp: kp}, nil
+}
+
+// trimNULCString trims a fixed-length, NUL-padded C string at its first NUL
+// byte and converts it to a Go string one byte at a time. kinfo_proc and
+// statfs(2) both return fields of this kind (e.g. [N]int8 on FreeBSD), and
+// converting the full padded buffer naively would leak trailing NUL bytes
+// into anything that prints or compares it.
+func trimNULCString(b []int8) string {
+	buf := make([]byte, 0, len(b))
+	for _, c := range b {
+		if c == 0 {
+			break
+		}
+		buf = append(buf, byte(c))
+	}
+	return string(buf)
+}
+
+// Status sets only PID, Name and PPid; all other ProcStatus fields are left
+// at their zero values.
+func (p freebsdProcessInfo) Status() (procfs.ProcStatus, error) {
+	return procfs.ProcStatus{
+		PID:  p.pid,
+		Name: trimNULCString(p.kp.Comm[:]),
+		PPid: int(p.kp.Ppid),
+	}, nil
+}
+
+func (p freebsdProcessInfo) IO() (procfs.ProcIO, error) {
+	return procfs.ProcIO{}, fmt.Errorf("proc: IO stats are not available on FreeBSD")
+}
+
+// Stat sets only PID, Comm and PPID; all other ProcStat fields are left at
+// their zero values.
+func (p freebsdProcessInfo) Stat() (procfs.ProcStat, error) {
+	return procfs.ProcStat{
+		PID:  p.pid,
+		Comm: trimNULCString(p.kp.Comm[:]),
+		PPID: int(p.kp.Ppid),
+	}, nil
+}
+
+func (p freebsdProcessInfo) Limits() (procfs.ProcLimits, error) {
+	return procfs.ProcLimits{}, fmt.Errorf("proc: resource limits are not available via sysctl on FreeBSD")
+}
@@ -0,0 +1,169 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build freebsd && amd64
+// +build freebsd,amd64
+
+package proc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// timeval mirrors FreeBSD's struct timeval as embedded in struct kinfo_proc.
+type timeval struct {
+	Sec  int64
+	Usec int64
+}
+
+// priority mirrors FreeBSD's struct priority.
+type priority struct {
+	Class  uint8
+	Level  uint8
+	Native uint8
+	User   uint8
+}
+
+// rusage mirrors FreeBSD's struct rusage as embedded in struct kinfo_proc.
+type rusage struct {
+	Utime    timeval
+	Stime    timeval
+	Maxrss   int64
+	Ixrss    int64
+	Idrss    int64
+	Isrss    int64
+	Minflt   int64
+	Majflt   int64
+	Nswap    int64
+	Inblock  int64
+	Oublock  int64
+	Msgsnd   int64
+	Msgrcv   int64
+	Nsignals int64
+	Nvcsw    int64
+	Nivcsw   int64
+}
+
+// kinfoProc mirrors amd64 FreeBSD's struct kinfo_proc, as defined in
+// sys/user.h, which is what the kern.proc.pid.N sysctl returns. It is not
+// provided by golang.org/x/sys/unix on this GOOS, and its layout is
+// architecture-specific, hence its own build-tagged file. Only the fields
+// this package currently surfaces are named individually; the large
+// reserved/spare regions are kept as opaque padding so the overall size and
+// offsets stay correct.
+type kinfoProc struct {
+	Structsize   int32
+	Layout       int32
+	Args         int64
+	Paddr        int64
+	Addr         int64
+	Tracep       int64
+	Textvp       int64
+	Fd           int64
+	Vmspace      int64
+	Wchan        int64
+	Pid          int32
+	Ppid         int32
+	Pgid         int32
+	Tpgid        int32
+	Sid          int32
+	Tsid         int32
+	Jobc         int16
+	SpareShort1  int16
+	Tdev         int32
+	Siglist      [16]byte
+	Sigmask      [16]byte
+	Sigignore    [16]byte
+	Sigcatch     [16]byte
+	Uid          uint32
+	Ruid         uint32
+	Svuid        uint32
+	Rgid         uint32
+	Svgid        uint32
+	Ngroups      int16
+	SpareShort2  int16
+	Groups       [16]uint32
+	Size         uint64
+	Rssize       int64
+	Swrss        int64
+	Tsize        int64
+	Dsize        int64
+	Ssize        int64
+	Xstat        uint16
+	Acflag       uint16
+	Pctcpu       uint32
+	Estcpu       uint32
+	Slptime      uint32
+	Swtime       uint32
+	Cow          uint32
+	Runtime      uint64
+	Start        timeval
+	Childtime    timeval
+	Flag         int64
+	Kiflag       int64
+	Traceflag    int32
+	Stat         int8
+	Nice         int8
+	Lock         int8
+	Rqindex      int8
+	Oncpu        uint8
+	Lastcpu      uint8
+	Tdname       [17]int8
+	Wmesg        [9]int8
+	Login        [18]int8
+	Lockname     [9]int8
+	Comm         [20]int8
+	Emul         [17]int8
+	SpareStrings [68]byte
+	SpareInts    [36]byte
+	CrFlags      uint32
+	Jid          int32
+	Numthreads   int32
+	Tid          int32
+	Pri          priority
+	Rusage       rusage
+	RusageCh     rusage
+	Pcb          int64
+	Kstack       int64
+	Udata        int64
+	Tdaddr       int64
+	SparePtrs    [6]int64
+	SpareLongs   [12]int64
+	Sflag        int64
+	Tdflags      int64
+}
+
+// sysctlKinfoProc fetches and unmarshals the kinfo_proc structure for pid
+// via the kern.proc.pid.N sysctl.
+func sysctlKinfoProc(pid int) (kinfoProc, error) {
+	raw, err := unix.SysctlRaw(fmt.Sprintf("kern.proc.pid.%d", pid))
+	if err != nil {
+		return kinfoProc{}, fmt.Errorf("proc: sysctl kern.proc.pid.%d: %w", pid, err)
+	}
+
+	var kp kinfoProc
+	size := binary.Size(kp)
+	if len(raw) < size {
+		return kinfoProc{}, fmt.Errorf("proc: kern.proc.pid.%d returned %d bytes, want at least %d (kernel/package kinfo_proc layout mismatch)", pid, len(raw), size)
+	}
+
+	if err := binary.Read(bytes.NewReader(raw[:size]), binary.LittleEndian, &kp); err != nil {
+		return kinfoProc{}, fmt.Errorf("proc: unmarshal kinfo_proc for pid %d: %w", pid, err)
+	}
+
+	return kp, nil
+}
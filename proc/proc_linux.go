@@ -0,0 +1,59 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package proc
+
+import "github.com/ncabatoff/procfs"
+
+// linuxProcessInfo implements ProcessInfo by delegating to procfs.Proc, so
+// its behavior is identical to using procfs.Proc directly.
+type linuxProcessInfo struct {
+	procfs.Proc
+}
+
+func newProcessInfo(pid int) (ProcessInfo, error) {
+	fs, err := procfs.NewDefaultFS()
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := fs.Proc(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	return linuxProcessInfo{p}, nil
+}
+
+// Status relies on procfs.Proc.NewStatus ignoring /proc/[pid]/status fields
+// it doesn't recognize by default, rather than erroring out, so that this
+// keeps working across kernel versions that add fields this package
+// predates.
+func (p linuxProcessInfo) Status() (procfs.ProcStatus, error) {
+	return p.Proc.NewStatus()
+}
+
+func (p linuxProcessInfo) IO() (procfs.ProcIO, error) {
+	return p.Proc.NewIO()
+}
+
+func (p linuxProcessInfo) Stat() (procfs.ProcStat, error) {
+	return p.Proc.NewStat()
+}
+
+func (p linuxProcessInfo) Limits() (procfs.ProcLimits, error) {
+	return p.Proc.NewLimits()
+}
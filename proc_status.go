@@ -17,108 +17,336 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 )
 
 // ProcStatus provides status information about the process,
 // read from /proc/[pid]/status.
-type (
-	ProcStatus struct {
-		TID                      int
-		TracerPid                int
-		UIDReal                  int
-		UIDEffective             int
-		UIDSavedSet              int
-		UIDFileSystem            int
-		GIDReal                  int
-		GIDEffective             int
-		GIDSavedSet              int
-		GIDFileSystem            int
-		FDSize                   int
-		VmPeakKB                 int
-		VmSizeKB                 int
-		VmLckKB                  int
-		VmHWMKB                  int
-		VmRSSKB                  int
-		VmDataKB                 int
-		VmStkKB                  int
-		VmExeKB                  int
-		VmLibKB                  int
-		VmPTEKB                  int
-		VmSwapKB                 int
-		VoluntaryCtxtSwitches    int
-		NonvoluntaryCtxtSwitches int
+type ProcStatus struct {
+	// The process ID.
+	PID int
+	// The process name.
+	Name string
+	// The state of the process.
+	State string
+	// The process ID of the parent process.
+	PPid int
+	// Thread group ID, i.e. the PID of the thread group leader.
+	Tgid int
+	// Thread ID, from the Pid line.
+	TID           int
+	TracerPid     int
+	UIDReal       int
+	UIDEffective  int
+	UIDSavedSet   int
+	UIDFileSystem int
+	GIDReal       int
+	GIDEffective  int
+	GIDSavedSet   int
+	GIDFileSystem int
+	// File mode creation mask, as an octal string (e.g. "0022").
+	Umask string
+	// Supplementary group IDs.
+	Groups []int
+	// Number of threads in the process.
+	Threads int
+	// Thread group ID as seen from each PID namespace the process is a
+	// member of, outermost first.
+	NStgid []int
+	// Process group ID as seen from each PID namespace.
+	NSpgid []int
+	// Session ID as seen from each PID namespace.
+	NSsid []int
+	// Namespaced group ID.
+	Ngid int
+	// Namespaced PIDs, one per namespace the process is visible in, innermost last.
+	NSpid []int
+	// Whether this is a kernel thread.
+	Kthread        bool
+	FDSize         int
+	VmPeakKB       int
+	VmSizeKB       int
+	VmLckKB        int
+	VmPinKB        int
+	VmHWMKB        int
+	VmRSSKB        int
+	RssAnonKB      int
+	RssFileKB      int
+	RssShmemKB     int
+	VmDataKB       int
+	VmStkKB        int
+	VmExeKB        int
+	VmLibKB        int
+	VmPTEKB        int
+	VmSwapKB       int
+	HugetlbPagesKB int
+	// Number of signals queued/max, as "SigQ: queued/limit".
+	SigQ string
+	// Pending signals for the whole process (as opposed to SigPnd, which is
+	// thread-specific), blocked, ignored, and caught signal bitmasks.
+	ShdPnd uint64
+	SigPnd uint64
+	SigBlk uint64
+	SigIgn uint64
+	SigCgt uint64
+	// Inherited, permitted, effective, bounding, and ambient capability bitmasks.
+	CapInh uint64
+	CapPrm uint64
+	CapEff uint64
+	CapBnd uint64
+	CapAmb uint64
+	// Whether the no_new_privs flag is set for the process.
+	NoNewPrivs bool
+	// Seccomp mode of the process.
+	Seccomp int
+	// Number of seccomp filters attached to the process.
+	SeccompFilters int
+	// Cpus_allowed, as a raw hex bitmask (e.g. "00000000,00000003").
+	CpusAllowed string
+	// Cpus_allowed_list, as reported by the kernel (e.g. "0-3").
+	CpusAllowedList string
+	// Mems_allowed, as a raw hex bitmask.
+	MemsAllowed string
+	// Mems_allowed_list, as reported by the kernel.
+	MemsAllowedList string
+	// Whether the process is currently dumping core.
+	CoreDumping bool
+	// Whether transparent huge pages are enabled for the process.
+	THPEnabled               bool
+	VoluntaryCtxtSwitches    int
+	NonvoluntaryCtxtSwitches int
+}
+
+// procStatusParser maps the field name found before the colon on each line
+// of /proc/[pid]/status to the handler that populates ProcStatus from the
+// remainder of that line.
+type procStatusParser func(ps *ProcStatus, value string) error
+
+var procStatusParsers = map[string]procStatusParser{
+	"Name":    procStatusParseString(func(ps *ProcStatus) *string { return &ps.Name }),
+	"State":   procStatusParseState,
+	"Tgid":    procStatusParseInt(func(ps *ProcStatus) *int { return &ps.Tgid }),
+	"Ngid":    procStatusParseInt(func(ps *ProcStatus) *int { return &ps.Ngid }),
+	"Pid":     procStatusParseInt(func(ps *ProcStatus) *int { return &ps.TID }),
+	"PPid":    procStatusParseInt(func(ps *ProcStatus) *int { return &ps.PPid }),
+	"Umask":   procStatusParseString(func(ps *ProcStatus) *string { return &ps.Umask }),
+	"Threads": procStatusParseInt(func(ps *ProcStatus) *int { return &ps.Threads }),
+	"Kthread": procStatusParseBool(func(ps *ProcStatus) *bool { return &ps.Kthread }),
+	"NStgid":  procStatusParseInts(func(ps *ProcStatus) *[]int { return &ps.NStgid }),
+	"NSpgid":  procStatusParseInts(func(ps *ProcStatus) *[]int { return &ps.NSpgid }),
+	"NSsid":   procStatusParseInts(func(ps *ProcStatus) *[]int { return &ps.NSsid }),
+	"NSpid":   procStatusParseInts(func(ps *ProcStatus) *[]int { return &ps.NSpid }),
+	"Groups":  procStatusParseInts(func(ps *ProcStatus) *[]int { return &ps.Groups }),
+	"Uid": func(ps *ProcStatus, v string) error {
+		return procStatusScanInts(v, &ps.UIDReal, &ps.UIDEffective, &ps.UIDSavedSet, &ps.UIDFileSystem)
+	},
+	"Gid": func(ps *ProcStatus, v string) error {
+		return procStatusScanInts(v, &ps.GIDReal, &ps.GIDEffective, &ps.GIDSavedSet, &ps.GIDFileSystem)
+	},
+	"TracerPid":                  procStatusParseInt(func(ps *ProcStatus) *int { return &ps.TracerPid }),
+	"FDSize":                     procStatusParseInt(func(ps *ProcStatus) *int { return &ps.FDSize }),
+	"VmPeak":                     procStatusParseKB(func(ps *ProcStatus) *int { return &ps.VmPeakKB }),
+	"VmSize":                     procStatusParseKB(func(ps *ProcStatus) *int { return &ps.VmSizeKB }),
+	"VmLck":                      procStatusParseKB(func(ps *ProcStatus) *int { return &ps.VmLckKB }),
+	"VmPin":                      procStatusParseKB(func(ps *ProcStatus) *int { return &ps.VmPinKB }),
+	"VmHWM":                      procStatusParseKB(func(ps *ProcStatus) *int { return &ps.VmHWMKB }),
+	"VmRSS":                      procStatusParseKB(func(ps *ProcStatus) *int { return &ps.VmRSSKB }),
+	"RssAnon":                    procStatusParseKB(func(ps *ProcStatus) *int { return &ps.RssAnonKB }),
+	"RssFile":                    procStatusParseKB(func(ps *ProcStatus) *int { return &ps.RssFileKB }),
+	"RssShmem":                   procStatusParseKB(func(ps *ProcStatus) *int { return &ps.RssShmemKB }),
+	"VmData":                     procStatusParseKB(func(ps *ProcStatus) *int { return &ps.VmDataKB }),
+	"VmStk":                      procStatusParseKB(func(ps *ProcStatus) *int { return &ps.VmStkKB }),
+	"VmExe":                      procStatusParseKB(func(ps *ProcStatus) *int { return &ps.VmExeKB }),
+	"VmLib":                      procStatusParseKB(func(ps *ProcStatus) *int { return &ps.VmLibKB }),
+	"VmPTE":                      procStatusParseKB(func(ps *ProcStatus) *int { return &ps.VmPTEKB }),
+	"VmSwap":                     procStatusParseKB(func(ps *ProcStatus) *int { return &ps.VmSwapKB }),
+	"HugetlbPages":               procStatusParseKB(func(ps *ProcStatus) *int { return &ps.HugetlbPagesKB }),
+	"SigQ":                       procStatusParseString(func(ps *ProcStatus) *string { return &ps.SigQ }),
+	"ShdPnd":                     procStatusParseHex(func(ps *ProcStatus) *uint64 { return &ps.ShdPnd }),
+	"SigPnd":                     procStatusParseHex(func(ps *ProcStatus) *uint64 { return &ps.SigPnd }),
+	"SigBlk":                     procStatusParseHex(func(ps *ProcStatus) *uint64 { return &ps.SigBlk }),
+	"SigIgn":                     procStatusParseHex(func(ps *ProcStatus) *uint64 { return &ps.SigIgn }),
+	"SigCgt":                     procStatusParseHex(func(ps *ProcStatus) *uint64 { return &ps.SigCgt }),
+	"CapInh":                     procStatusParseHex(func(ps *ProcStatus) *uint64 { return &ps.CapInh }),
+	"CapPrm":                     procStatusParseHex(func(ps *ProcStatus) *uint64 { return &ps.CapPrm }),
+	"CapEff":                     procStatusParseHex(func(ps *ProcStatus) *uint64 { return &ps.CapEff }),
+	"CapBnd":                     procStatusParseHex(func(ps *ProcStatus) *uint64 { return &ps.CapBnd }),
+	"CapAmb":                     procStatusParseHex(func(ps *ProcStatus) *uint64 { return &ps.CapAmb }),
+	"NoNewPrivs":                 procStatusParseBool(func(ps *ProcStatus) *bool { return &ps.NoNewPrivs }),
+	"Seccomp":                    procStatusParseInt(func(ps *ProcStatus) *int { return &ps.Seccomp }),
+	"Seccomp_filters":            procStatusParseInt(func(ps *ProcStatus) *int { return &ps.SeccompFilters }),
+	"Cpus_allowed":               procStatusParseString(func(ps *ProcStatus) *string { return &ps.CpusAllowed }),
+	"Cpus_allowed_list":          procStatusParseString(func(ps *ProcStatus) *string { return &ps.CpusAllowedList }),
+	"Mems_allowed":               procStatusParseString(func(ps *ProcStatus) *string { return &ps.MemsAllowed }),
+	"Mems_allowed_list":          procStatusParseString(func(ps *ProcStatus) *string { return &ps.MemsAllowedList }),
+	"CoreDumping":                procStatusParseBool(func(ps *ProcStatus) *bool { return &ps.CoreDumping }),
+	"THP_enabled":                procStatusParseBool(func(ps *ProcStatus) *bool { return &ps.THPEnabled }),
+	"voluntary_ctxt_switches":    procStatusParseInt(func(ps *ProcStatus) *int { return &ps.VoluntaryCtxtSwitches }),
+	"nonvoluntary_ctxt_switches": procStatusParseInt(func(ps *ProcStatus) *int { return &ps.NonvoluntaryCtxtSwitches }),
+}
+
+func procStatusParseString(field func(*ProcStatus) *string) procStatusParser {
+	return func(ps *ProcStatus, v string) error {
+		*field(ps) = v
+		return nil
 	}
+}
 
-	procStatusScanner struct {
-		format string
-		args   []interface{}
+func procStatusParseState(ps *ProcStatus, v string) error {
+	// Value looks like "R (running)"; keep only the one-letter state code.
+	fields := strings.Fields(v)
+	if len(fields) == 0 {
+		return fmt.Errorf("empty State line")
 	}
+	ps.State = fields[0]
+	return nil
+}
 
-	procStatusBuilder struct {
-		ps       ProcStatus
-		scanners []procStatusScanner
+func procStatusParseInt(field func(*ProcStatus) *int) procStatusParser {
+	return func(ps *ProcStatus, v string) error {
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return err
+		}
+		*field(ps) = i
+		return nil
 	}
-)
+}
 
-func newProcStatusBuilder() *procStatusBuilder {
-	var b procStatusBuilder
-	b.scanners = []procStatusScanner{
-		{"Pid: %d", []interface{}{&b.ps.TID}},
-		{"TracerPid: %d", []interface{}{&b.ps.TracerPid}},
-		{"Uid: %d %d %d %d", []interface{}{
-			&b.ps.UIDReal,
-			&b.ps.UIDEffective,
-			&b.ps.UIDSavedSet,
-			&b.ps.UIDFileSystem,
-		}},
-		{"Gid: %d %d %d %d", []interface{}{
-			&b.ps.GIDReal,
-			&b.ps.GIDEffective,
-			&b.ps.GIDSavedSet,
-			&b.ps.GIDFileSystem,
-		}},
-		{"FDSize: %d", []interface{}{&b.ps.FDSize}},
-		{"VmPeak: %d kB", []interface{}{&b.ps.VmPeakKB}},
-		{"VmSize: %d kB", []interface{}{&b.ps.VmSizeKB}},
-		{"VmLck:  %d kB", []interface{}{&b.ps.VmLckKB}},
-		{"VmHWM:  %d kB", []interface{}{&b.ps.VmHWMKB}},
-		{"VmRSS:  %d kB", []interface{}{&b.ps.VmRSSKB}},
-		{"VmData: %d kB", []interface{}{&b.ps.VmDataKB}},
-		{"VmStk:  %d kB", []interface{}{&b.ps.VmStkKB}},
-		{"VmExe:  %d kB", []interface{}{&b.ps.VmExeKB}},
-		{"VmLib:  %d kB", []interface{}{&b.ps.VmLibKB}},
-		{"VmPTE:  %d kB", []interface{}{&b.ps.VmPTEKB}},
-		{"VmSwap: %d kB", []interface{}{&b.ps.VmSwapKB}},
-		{"voluntary_ctxt_switches:    %d", []interface{}{&b.ps.VoluntaryCtxtSwitches}},
-		{"nonvoluntary_ctxt_switches: %d", []interface{}{&b.ps.NonvoluntaryCtxtSwitches}},
+// procStatusParseKB parses a value of the form "1234 kB".
+func procStatusParseKB(field func(*ProcStatus) *int) procStatusParser {
+	return func(ps *ProcStatus, v string) error {
+		v = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(v), "kB"))
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return err
+		}
+		*field(ps) = i
+		return nil
 	}
-	return &b
 }
 
-func (b *procStatusBuilder) readStatus(r *bufio.Reader) (ProcStatus, error) {
-	for _, s := range b.scanners {
-		for {
-			line, err := r.ReadString('\n')
+func procStatusParseHex(field func(*ProcStatus) *uint64) procStatusParser {
+	return func(ps *ProcStatus, v string) error {
+		u, err := strconv.ParseUint(strings.TrimSpace(v), 16, 64)
+		if err != nil {
+			return err
+		}
+		*field(ps) = u
+		return nil
+	}
+}
+
+func procStatusParseBool(field func(*ProcStatus) *bool) procStatusParser {
+	return func(ps *ProcStatus, v string) error {
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return err
+		}
+		*field(ps) = i != 0
+		return nil
+	}
+}
+
+// procStatusParseInts parses a whitespace-separated list of ints, as found
+// on the Groups and NSpid lines.
+func procStatusParseInts(field func(*ProcStatus) *[]int) procStatusParser {
+	return func(ps *ProcStatus, v string) error {
+		fields := strings.Fields(v)
+		ints := make([]int, 0, len(fields))
+		for _, f := range fields {
+			i, err := strconv.Atoi(f)
 			if err != nil {
-				return ProcStatus{}, err
+				return err
 			}
+			ints = append(ints, i)
+		}
+		*field(ps) = ints
+		return nil
+	}
+}
+
+func procStatusScanInts(v string, dst ...*int) error {
+	fields := strings.Fields(v)
+	if len(fields) < len(dst) {
+		return fmt.Errorf("expected %d fields, got %q", len(dst), v)
+	}
+	for i, d := range dst {
+		n, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return err
+		}
+		*d = n
+	}
+	return nil
+}
+
+// readStatus reads /proc/[pid]/status from r, dispatching each line on the
+// field name preceding the colon. The kernel regularly adds fields to this
+// file across versions, and also omits some for kernel threads, so by
+// default (strict is false) a line with no registered handler is simply
+// skipped. Passing strict lets callers that want to detect fields this
+// package doesn't yet know about opt into treating that as an error instead.
+func readStatus(r *bufio.Reader, strict bool) (ProcStatus, error) {
+	var ps ProcStatus
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key := strings.TrimSpace(name)
+		val := strings.TrimSpace(value)
 
-			_, err = fmt.Sscanf(line, s.format, s.args...)
-			if err == nil {
-				break
+		parser, ok := procStatusParsers[key]
+		if !ok {
+			if !strict {
+				continue
 			}
+			return ProcStatus{}, fmt.Errorf("unrecognized /proc/[pid]/status field %q", key)
+		}
+
+		if err := parser(&ps, val); err != nil {
+			return ProcStatus{}, fmt.Errorf("failed to parse /proc/[pid]/status field %q: %w", key, err)
 		}
 	}
-	return b.ps, nil
+	if err := scanner.Err(); err != nil {
+		return ProcStatus{}, err
+	}
+
+	ps.PID = ps.TID
+	return ps, nil
 }
 
-// NewStatus returns the current status information of the process.
+// NewStatus returns the current status information of the process. Fields
+// in /proc/[pid]/status that this package does not recognize are ignored
+// rather than treated as an error, since the kernel adds new ones across
+// versions and omits some for kernel threads; use NewStatusStrict to
+// instead fail on those so newly-added fields don't go unnoticed.
 func (p Proc) NewStatus() (ProcStatus, error) {
+	return p.newStatus(false)
+}
+
+// NewStatusStrict returns the current status information of the process,
+// like NewStatus, but returns an error if /proc/[pid]/status contains any
+// field this package does not recognize.
+func (p Proc) NewStatusStrict() (ProcStatus, error) {
+	return p.newStatus(true)
+}
+
+func (p Proc) newStatus(strict bool) (ProcStatus, error) {
 	f, err := os.Open(p.path("status"))
 	if err != nil {
 		return ProcStatus{}, err
 	}
 	defer f.Close()
 
-	return newProcStatusBuilder().readStatus(bufio.NewReader(f))
+	return readStatus(bufio.NewReader(f), strict)
 }
@@ -0,0 +1,158 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package procfs
+
+import (
+	"bufio"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func readStatusFixture(t *testing.T, path string, strict bool) (ProcStatus, error) {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	return readStatus(bufio.NewReader(f), strict)
+}
+
+func TestReadStatusNonfatal(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		want    ProcStatus
+	}{
+		{
+			name:    "normal process",
+			fixture: "fixtures/proc/26231/status",
+			want: ProcStatus{
+				PID:                      26231,
+				Name:                     "bash",
+				State:                    "S",
+				PPid:                     26219,
+				Tgid:                     26231,
+				TID:                      26231,
+				UIDReal:                  1000,
+				UIDEffective:             1000,
+				UIDSavedSet:              1000,
+				UIDFileSystem:            1000,
+				GIDReal:                  1000,
+				GIDEffective:             1000,
+				GIDSavedSet:              1000,
+				GIDFileSystem:            1000,
+				Umask:                    "0022",
+				Groups:                   []int{4, 24, 27, 30, 46, 108, 1000},
+				Threads:                  1,
+				NStgid:                   []int{26231},
+				NSpgid:                   []int{26231},
+				NSsid:                    []int{26219},
+				NSpid:                    []int{26231},
+				FDSize:                   256,
+				VmPeakKB:                 19612,
+				VmSizeKB:                 19612,
+				VmHWMKB:                  4352,
+				VmRSSKB:                  4352,
+				RssAnonKB:                1212,
+				RssFileKB:                3140,
+				VmDataKB:                 1932,
+				VmStkKB:                  136,
+				VmExeKB:                  832,
+				VmLibKB:                  2208,
+				VmPTEKB:                  52,
+				HugetlbPagesKB:           0,
+				CoreDumping:              false,
+				THPEnabled:               true,
+				SigQ:                     "0/31603",
+				SigPnd:                   0,
+				ShdPnd:                   0,
+				SigBlk:                   0x10000,
+				SigIgn:                   0x384004,
+				SigCgt:                   0x4b817efb,
+				CapInh:                   0,
+				CapPrm:                   0,
+				CapEff:                   0,
+				CapBnd:                   0x3fffffffff,
+				CapAmb:                   0,
+				NoNewPrivs:               false,
+				Seccomp:                  0,
+				SeccompFilters:           0,
+				CpusAllowed:              "ffffffff",
+				CpusAllowedList:          "0-31",
+				MemsAllowed:              "00000000,00000001",
+				MemsAllowedList:          "0",
+				VoluntaryCtxtSwitches:    42,
+				NonvoluntaryCtxtSwitches: 3,
+			},
+		},
+		{
+			name:    "kernel thread",
+			fixture: "fixtures/proc/2/status",
+			want: ProcStatus{
+				PID:                      2,
+				Name:                     "kthreadd",
+				State:                    "S",
+				PPid:                     0,
+				Tgid:                     2,
+				TID:                      2,
+				Umask:                    "0000",
+				Groups:                   []int{},
+				Kthread:                  true,
+				Threads:                  1,
+				FDSize:                   64,
+				NStgid:                   []int{2},
+				NSpgid:                   []int{0},
+				NSsid:                    []int{0},
+				SigQ:                     "0/31603",
+				SigIgn:                   0xffffffffffffffff,
+				CapPrm:                   0x3fffffffff,
+				CapEff:                   0x3fffffffff,
+				CapBnd:                   0x3fffffffff,
+				VoluntaryCtxtSwitches:    2,
+				NonvoluntaryCtxtSwitches: 0,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readStatusFixture(t, tt.fixture, false)
+			if err != nil {
+				t.Fatalf("readStatus (nonfatal): unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readStatus (nonfatal) =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadStatusStrict(t *testing.T) {
+	// The normal-process fixture includes Speculation_Store_Bypass, a real
+	// modern kernel field this package doesn't model, so strict mode must
+	// reject it.
+	if _, err := readStatusFixture(t, "fixtures/proc/26231/status", true); err == nil {
+		t.Error("readStatus (strict) on fixture with an unrecognized field: expected error, got nil")
+	}
+
+	// The kernel-thread fixture sticks to fields this package knows about,
+	// so strict mode should accept it even though many fields are absent.
+	if _, err := readStatusFixture(t, "fixtures/proc/2/status", true); err != nil {
+		t.Errorf("readStatus (strict) on fixture with only known fields: unexpected error: %v", err)
+	}
+}